@@ -0,0 +1,79 @@
+package pgxgeom_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/twpayne/go-geom"
+
+	pgxgeom "github.com/twpayne/pgx-geom"
+)
+
+func TestCopyFromGeoms(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, tb testing.TB, conn *pgx.Conn) {
+		tb.Helper()
+
+		_, err := conn.Exec(ctx, "create temporary table copy_from_geoms_test (geom geometry(point, 4326))")
+		assert.NoError(tb, err)
+
+		geoms := []geom.T{
+			mustNewGeomFromWKT(tb, "POINT(1 2)", 4326),
+			mustNewGeomFromWKT(tb, "POINT(3 4)", 4326),
+		}
+
+		n, err := pgxgeom.CopyFromGeoms(ctx, conn, pgx.Identifier{"copy_from_geoms_test"}, []string{"geom"}, geoms)
+		assert.NoError(tb, err)
+		assert.Equal(tb, int64(len(geoms)), n)
+
+		var count int
+		err = conn.QueryRow(ctx, "select count(*) from copy_from_geoms_test").Scan(&count)
+		assert.NoError(tb, err)
+		assert.Equal(tb, len(geoms), count)
+	})
+}
+
+func benchmarkGeoms(n int) []geom.T {
+	geoms := make([]geom.T, n)
+	for i := range geoms {
+		geoms[i] = geom.NewPointFlat(geom.XY, []float64{float64(i), float64(i)}).SetSRID(4326)
+	}
+	return geoms
+}
+
+func BenchmarkCopyFromGeoms(b *testing.B) {
+	defaultConnTestRunner.RunTest(context.Background(), b, func(ctx context.Context, tb testing.TB, conn *pgx.Conn) {
+		tb.Helper()
+		_, err := conn.Exec(ctx, "create temporary table copy_from_geoms_bench (geom geometry(point, 4326))")
+		assert.NoError(tb, err)
+
+		geoms := benchmarkGeoms(10_000)
+		bb := tb.(*testing.B)
+		bb.ResetTimer()
+		for i := 0; i < bb.N; i++ {
+			_, err := pgxgeom.CopyFromGeoms(ctx, conn, pgx.Identifier{"copy_from_geoms_bench"}, []string{"geom"}, geoms)
+			assert.NoError(tb, err)
+		}
+	})
+}
+
+func BenchmarkInsertGeoms(b *testing.B) {
+	defaultConnTestRunner.RunTest(context.Background(), b, func(ctx context.Context, tb testing.TB, conn *pgx.Conn) {
+		tb.Helper()
+		_, err := conn.Exec(ctx, "create temporary table insert_geoms_bench (geom geometry(point, 4326))")
+		assert.NoError(tb, err)
+
+		geoms := benchmarkGeoms(10_000)
+		bb := tb.(*testing.B)
+		bb.ResetTimer()
+		for i := 0; i < bb.N; i++ {
+			batch := &pgx.Batch{}
+			for _, g := range geoms {
+				batch.Queue("insert into insert_geoms_bench (geom) values ($1)", g)
+			}
+			err := conn.SendBatch(ctx, batch).Close()
+			assert.NoError(tb, err)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package pgxgeom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+// GeoJSONValuer is implemented by types that can produce their own RFC 7946
+// GeoJSON encoding to be sent as a geometry/geography column value. It is
+// the GeoJSON counterpart of GeomValue.
+type GeoJSONValuer interface {
+	GeoJSONValue() ([]byte, error)
+}
+
+// ScanGeoJSON is implemented by types that want to receive a decoded
+// geometry as RFC 7946 GeoJSON rather than a go-geom value. It is the
+// GeoJSON counterpart of ScanGeom.
+type ScanGeoJSON interface {
+	ScanGeoJSON([]byte) error
+}
+
+// GeoJSON wraps a geom.T so it can be used as a query argument or Scan
+// target that reads and writes RFC 7946 GeoJSON on the Go side, while still
+// going over the wire as PostGIS EWKB.
+type GeoJSON struct {
+	Geom geom.T
+}
+
+func (g GeoJSON) GeoJSONValue() ([]byte, error) {
+	data, err := geojson.Marshal(g.Geom)
+	if err != nil {
+		return nil, fmt.Errorf("pgxgeom: failed to marshal GeoJSON: %w", err)
+	}
+	return data, nil
+}
+
+func (g *GeoJSON) ScanGeoJSON(data []byte) error {
+	var decoded geom.T
+	if err := geojson.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("pgxgeom: failed to unmarshal GeoJSON: %w", err)
+	}
+	g.Geom = decoded
+	return nil
+}
+
+type encodeGeoJSONValuerPlan struct {
+	codec Codec
+}
+
+func (p encodeGeoJSONValuerPlan) Encode(value any, buf []byte) ([]byte, error) {
+	data, err := value.(GeoJSONValuer).GeoJSONValue()
+	if err != nil {
+		return nil, fmt.Errorf("pgxgeom: failed to get GeoJSON value from %T: %w", value, err)
+	}
+	return encodeRawGeoJSON(p.codec, data, buf)
+}
+
+type encodeRawMessagePlan struct {
+	codec Codec
+}
+
+func (p encodeRawMessagePlan) Encode(value any, buf []byte) ([]byte, error) {
+	raw := value.(*json.RawMessage)
+	return encodeRawGeoJSON(p.codec, *raw, buf)
+}
+
+// encodeRawGeoJSON converts GeoJSON data to a go-geom value and encodes it
+// as EWKB. Like encodePlan, it checks constraints against go-geom directly
+// (via checkGoGeomConstraints) rather than through the codec's configured
+// GeomCodec backend: GeoJSON always decodes into a geom.T, so constraint
+// checks here must too, even when a non-default GeomCodec (e.g.
+// pgxgeom/orb) is registered alongside GeoJSON.
+func encodeRawGeoJSON(codec Codec, data []byte, buf []byte) ([]byte, error) {
+	var g geom.T
+	if err := geojson.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("pgxgeom: failed to unmarshal GeoJSON: %w", err)
+	}
+	if err := codec.checkGoGeomConstraints(g); err != nil {
+		return nil, err
+	}
+	out, err := ewkb.Marshal(g, ewkbByteOrder)
+	if err != nil {
+		return nil, fmt.Errorf("pgxgeom: failed to encode %T: %w", g, err)
+	}
+	codec.tel.recordEncode(context.Background(), g, len(out), pgx.BinaryFormatCode)
+	return append(buf, out...), nil
+}
+
+type scanGeoJSONPlan struct {
+	codec  Codec
+	format int16
+}
+
+func (p scanGeoJSONPlan) Scan(src []byte, target any) error {
+	dst := target.(ScanGeoJSON)
+	if src == nil {
+		return dst.ScanGeoJSON(nil)
+	}
+	start := time.Now()
+	raw, err := rawEWKBBytes(src, p.format)
+	if err != nil {
+		return err
+	}
+	g, unmarshalErr := ewkb.Unmarshal(raw)
+	err = unmarshalErr
+	if err == nil {
+		err = p.codec.checkGoGeomConstraints(g)
+	}
+	p.codec.tel.recordDecode(context.Background(), g, len(raw), p.format, time.Since(start), err)
+	if unmarshalErr != nil {
+		return fmt.Errorf("pgxgeom: failed to decode: %w", unmarshalErr)
+	}
+	if err != nil {
+		return err
+	}
+	data, err := geojson.Marshal(g)
+	if err != nil {
+		return fmt.Errorf("pgxgeom: failed to marshal GeoJSON: %w", err)
+	}
+	return dst.ScanGeoJSON(data)
+}
+
+type scanRawMessagePlan struct {
+	codec  Codec
+	format int16
+}
+
+func (p scanRawMessagePlan) Scan(src []byte, target any) error {
+	dst := target.(*json.RawMessage)
+	if src == nil {
+		*dst = nil
+		return nil
+	}
+	start := time.Now()
+	raw, err := rawEWKBBytes(src, p.format)
+	if err != nil {
+		return err
+	}
+	g, unmarshalErr := ewkb.Unmarshal(raw)
+	err = unmarshalErr
+	if err == nil {
+		err = p.codec.checkGoGeomConstraints(g)
+	}
+	p.codec.tel.recordDecode(context.Background(), g, len(raw), p.format, time.Since(start), err)
+	if unmarshalErr != nil {
+		return fmt.Errorf("pgxgeom: failed to decode: %w", unmarshalErr)
+	}
+	if err != nil {
+		return err
+	}
+	data, err := geojson.Marshal(g)
+	if err != nil {
+		return fmt.Errorf("pgxgeom: failed to marshal GeoJSON: %w", err)
+	}
+	*dst = data
+	return nil
+}
@@ -0,0 +1,53 @@
+package pgxgeom_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+
+	pgxgeom "github.com/twpayne/pgx-geom"
+)
+
+var geoJSONConnTestRunner pgxtest.ConnTestRunner
+
+func init() {
+	geoJSONConnTestRunner = pgxtest.DefaultConnTestRunner()
+	geoJSONConnTestRunner.AfterConnect = func(ctx context.Context, tb testing.TB, conn *pgx.Conn) {
+		tb.Helper()
+		_, err := conn.Exec(ctx, "create extension if not exists postgis")
+		assert.NoError(tb, err)
+		assert.NoError(tb, pgxgeom.Register(ctx, conn, pgxgeom.RegisterOptions{GeoJSON: true}))
+	}
+}
+
+func TestCodecEncodeDecodeRawMessage(t *testing.T) {
+	geoJSONConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, tb testing.TB, conn *pgx.Conn) {
+		tb.Helper()
+
+		raw := json.RawMessage(`{"type":"Point","coordinates":[1,2]}`)
+		var out json.RawMessage
+		err := conn.QueryRow(ctx, "select ST_SetSRID($1::geometry, 4326)", &raw).Scan(&out)
+		assert.NoError(t, err)
+
+		var got, want map[string]any
+		assert.NoError(t, json.Unmarshal(out, &got))
+		assert.NoError(t, json.Unmarshal(raw, &want))
+		assert.Equal(t, want["type"], got["type"])
+	})
+}
+
+func TestCodecEncodeDecodeGeoJSON(t *testing.T) {
+	geoJSONConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, tb testing.TB, conn *pgx.Conn) {
+		tb.Helper()
+
+		in := pgxgeom.GeoJSON{Geom: mustNewGeomFromWKT(tb, "POINT(1 2)", 4326)}
+		var out pgxgeom.GeoJSON
+		err := conn.QueryRow(ctx, "select ST_SetSRID($1::geometry, 4326)", in).Scan(&out)
+		assert.NoError(t, err)
+		assert.Equal(t, in.Geom.FlatCoords(), out.Geom.FlatCoords())
+	})
+}
@@ -0,0 +1,63 @@
+package pgxgeom
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+)
+
+// GeomCodec abstracts the geometry backend a Codec marshals and unmarshals
+// values with, so that applications already committed to an ecosystem other
+// than go-geom (see the pgxgeom/orb and pgxgeom/simplefeatures subpackages)
+// can register the PostGIS type against their own geometry type instead of
+// converting through geom.T on every row. The zero value of Codec uses
+// goGeomCodec, backed by twpayne/go-geom.
+type GeomCodec interface {
+	// Is reports whether value is an instance of the backend's geometry
+	// type, used to route encoding of raw (unwrapped) values.
+	Is(value any) bool
+
+	// Marshal encodes a backend geometry value to EWKB.
+	Marshal(value any) ([]byte, error)
+
+	// Unmarshal decodes EWKB into a backend geometry value.
+	Unmarshal(data []byte) (any, error)
+
+	// SRID returns the SRID of a backend geometry value, used to enforce
+	// RegisterOptions.AllowedSRIDs.
+	SRID(value any) int
+
+	// HasZM reports whether a backend geometry value carries a Z or M
+	// dimension, used to enforce RegisterOptions.RejectZM.
+	HasZM(value any) bool
+}
+
+// goGeomCodec is the default GeomCodec, backed by twpayne/go-geom.
+type goGeomCodec struct{}
+
+func (goGeomCodec) Is(value any) bool {
+	_, ok := value.(geom.T)
+	return ok
+}
+
+func (goGeomCodec) Marshal(value any) ([]byte, error) {
+	g, ok := value.(geom.T)
+	if !ok {
+		return nil, fmt.Errorf("pgxgeom: %T is not a geom.T", value)
+	}
+	return ewkb.Marshal(g, ewkbByteOrder)
+}
+
+func (goGeomCodec) Unmarshal(data []byte) (any, error) {
+	return ewkb.Unmarshal(data)
+}
+
+func (goGeomCodec) SRID(value any) int {
+	return value.(geom.T).SRID()
+}
+
+func (goGeomCodec) HasZM(value any) bool {
+	layout := value.(geom.T).Layout()
+	return layout.ZIndex() >= 0 || layout.MIndex() >= 0
+}
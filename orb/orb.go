@@ -0,0 +1,84 @@
+// Package orb registers the PostGIS geometry and geography types against
+// paulmach/orb geometries instead of pgxgeom's default twpayne/go-geom
+// backend, for applications already committed to the orb ecosystem.
+package orb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/wkb"
+
+	pgxgeom "github.com/twpayne/pgx-geom"
+)
+
+// GeomCodec implements pgxgeom.GeomCodec backed by paulmach/orb.
+//
+// orb.Geometry carries neither an SRID nor a Z/M dimension, so SRID always
+// returns 0 and HasZM always returns false: RegisterOptions.RejectZM has no
+// effect on orb values, and a non-empty RegisterOptions.AllowedSRIDs rejects
+// every orb value unless it lists 0. Pin the SRID with a typmod column
+// (geometry(Point,4326)) instead.
+type GeomCodec struct{}
+
+func (GeomCodec) Is(value any) bool {
+	_, ok := value.(orb.Geometry)
+	return ok
+}
+
+func (GeomCodec) Marshal(value any) ([]byte, error) {
+	g, ok := value.(orb.Geometry)
+	if !ok {
+		return nil, fmt.Errorf("pgxgeom/orb: %T is not an orb.Geometry", value)
+	}
+	return wkb.Marshal(g)
+}
+
+func (GeomCodec) Unmarshal(data []byte) (any, error) {
+	g, err := wkb.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (GeomCodec) SRID(any) int {
+	return 0
+}
+
+func (GeomCodec) HasZM(any) bool {
+	return false
+}
+
+// Geom wraps an orb.Geometry so it can be used as a query argument or Scan
+// target, implementing pgxgeom.ScanGeom and pgxgeom.GeomValue.
+type Geom struct {
+	Geometry orb.Geometry
+}
+
+func (g *Geom) ScanGeom(v any) error {
+	geometry, ok := v.(orb.Geometry)
+	if !ok {
+		return fmt.Errorf("pgxgeom/orb: got %T, want orb.Geometry", v)
+	}
+	g.Geometry = geometry
+	return nil
+}
+
+func (g Geom) GeomValue() (any, error) {
+	return g.Geometry, nil
+}
+
+// Register registers the PostGIS geometry type (and, unless overridden via
+// opts, the geography type) on conn, backed by paulmach/orb rather than
+// pgxgeom's default twpayne/go-geom.
+func Register(ctx context.Context, conn *pgx.Conn, opts ...pgxgeom.RegisterOptions) error {
+	var opt pgxgeom.RegisterOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.GeomCodec = GeomCodec{}
+	return pgxgeom.Register(ctx, conn, opt)
+}
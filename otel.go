@@ -0,0 +1,135 @@
+package pgxgeom
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/twpayne/go-geom"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/twpayne/pgx-geom"
+
+// telemetry holds the OpenTelemetry providers wired into a Codec via
+// WithTracerProvider/WithMeterProvider. pgtype's EncodePlan.Encode and
+// ScanPlan.Scan don't receive a context at all, so every span recorded here
+// is always a new root span, never a child of the query span; it still
+// carries the SRID/geom_type/size attributes needed to spot a pathological
+// giant-multipolygon row.
+type telemetry struct {
+	tracer        trace.Tracer
+	payloadSize   metric.Int64Histogram
+	decodeLatency metric.Float64Histogram
+}
+
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *telemetry {
+	if tp == nil && mp == nil {
+		return nil
+	}
+
+	t := &telemetry{}
+	if tp != nil {
+		t.tracer = tp.Tracer(instrumentationName)
+	}
+	if mp != nil {
+		meter := mp.Meter(instrumentationName)
+
+		payloadSize, err := meter.Int64Histogram(
+			"pgxgeom.payload.bytes",
+			metric.WithDescription("Size in bytes of an encoded or decoded EWKB payload"),
+			metric.WithUnit("By"),
+		)
+		if err != nil {
+			panic(fmt.Sprintf("pgxgeom: failed to create payload size histogram: %v", err))
+		}
+		t.payloadSize = payloadSize
+
+		decodeLatency, err := meter.Float64Histogram(
+			"pgxgeom.decode.duration",
+			metric.WithDescription("Time spent decoding a geometry/geography value"),
+			metric.WithUnit("ms"),
+		)
+		if err != nil {
+			panic(fmt.Sprintf("pgxgeom: failed to create decode latency histogram: %v", err))
+		}
+		t.decodeLatency = decodeLatency
+	}
+	return t
+}
+
+func formatName(format int16) string {
+	if format == 0 {
+		return "text"
+	}
+	return "binary"
+}
+
+// geomAttributes best-effort describes g for span/metric attributes. It
+// recognizes go-geom values directly; other backends still get geom_type,
+// bytes and format, just not srid or num_points.
+func geomAttributes(g any, numBytes int, format int16) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("pgxgeom.geom_type", fmt.Sprintf("%T", g)),
+		attribute.Int("pgxgeom.bytes", numBytes),
+		attribute.String("pgxgeom.format", formatName(format)),
+	}
+	if gt, ok := g.(geom.T); ok {
+		attrs = append(attrs,
+			attribute.Int("pgxgeom.srid", gt.SRID()),
+			attribute.Int("pgxgeom.num_points", len(gt.FlatCoords())/maxInt(gt.Stride(), 1)),
+		)
+	}
+	return attrs
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// recordDecode emits a span (always a new root span; see telemetry's doc
+// comment) and histograms for a decode of numBytes of EWKB into g, which
+// took dur.
+func (t *telemetry) recordDecode(ctx context.Context, g any, numBytes int, format int16, dur time.Duration, decodeErr error) {
+	if t == nil {
+		return
+	}
+	attrs := geomAttributes(g, numBytes, format)
+
+	if t.tracer != nil {
+		_, span := t.tracer.Start(ctx, "pgxgeom.decode", trace.WithAttributes(attrs...))
+		if decodeErr != nil {
+			span.RecordError(decodeErr)
+		}
+		span.End()
+	}
+	if t.payloadSize != nil {
+		t.payloadSize.Record(ctx, int64(numBytes), metric.WithAttributes(attrs...))
+	}
+	if t.decodeLatency != nil {
+		t.decodeLatency.Record(ctx, float64(dur)/float64(time.Millisecond), metric.WithAttributes(attrs...))
+	}
+}
+
+// recordEncode emits a span (always a new root span; see telemetry's doc
+// comment) and a payload size histogram for an encode of g into numBytes of
+// EWKB.
+func (t *telemetry) recordEncode(ctx context.Context, g any, numBytes int, format int16) {
+	if t == nil {
+		return
+	}
+	attrs := geomAttributes(g, numBytes, format)
+
+	if t.tracer != nil {
+		_, span := t.tracer.Start(ctx, "pgxgeom.encode", trace.WithAttributes(attrs...))
+		span.End()
+	}
+	if t.payloadSize != nil {
+		t.payloadSize.Record(ctx, int64(numBytes), metric.WithAttributes(attrs...))
+	}
+}
@@ -0,0 +1,32 @@
+package pgxgeom_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/twpayne/go-geom"
+	"go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	pgxgeom "github.com/twpayne/pgx-geom"
+)
+
+func TestRegisterWithOTelProviders(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, tb testing.TB, conn *pgx.Conn) {
+		tb.Helper()
+
+		err := pgxgeom.Register(ctx, conn, pgxgeom.RegisterOptions{
+			TracerProvider: tracenoop.NewTracerProvider(),
+			MeterProvider:  noop.NewMeterProvider(),
+		})
+		assert.NoError(tb, err)
+
+		original := mustNewGeomFromWKT(tb, "POINT(1 2)", 4326)
+		var got geom.T
+		err = conn.QueryRow(ctx, "select $1::geometry", original).Scan(&got)
+		assert.NoError(tb, err)
+		assert.Equal(tb, original.FlatCoords(), got.FlatCoords())
+	})
+}
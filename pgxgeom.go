@@ -0,0 +1,587 @@
+// Package pgxgeom provides PostGIS geometry and geography support for pgx,
+// encoding and decoding column values as github.com/twpayne/go-geom types
+// over the PostGIS EWKB wire format.
+package pgxgeom
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScanGeom is implemented by types that want to receive a decoded geometry
+// directly, instead of being scanned into via reflection. It is the scan-side
+// counterpart of GeomValue. The value passed is whatever the registered
+// GeomCodec unmarshals EWKB into: a geom.T for the default backend, or the
+// equivalent type for an alternate backend such as pgxgeom/orb.
+type ScanGeom interface {
+	ScanGeom(any) error
+}
+
+// GeomValue is implemented by types that can produce a geometry to be
+// encoded on the wire. It is the encode-side counterpart of ScanGeom. The
+// returned value must be a type the registered GeomCodec knows how to
+// marshal.
+type GeomValue interface {
+	GeomValue() (any, error)
+}
+
+// Codec implements pgtype.Codec for a PostGIS geometry-family column,
+// marshaling and unmarshaling values as EWKB.
+type Codec struct {
+	// allowedSRIDs, if non-empty, restricts the SRIDs this codec will
+	// encode or decode; any other SRID is rejected with an error. Set via
+	// RegisterOptions.AllowedSRIDs.
+	allowedSRIDs []int
+
+	// rejectZM rejects geometries carrying a Z or M dimension when set.
+	// Set via RegisterOptions.RejectZM.
+	rejectZM bool
+
+	// geojson enables *json.RawMessage and GeoJSONValuer/ScanGeoJSON as
+	// additional encode/scan targets, converting to/from RFC 7946 GeoJSON.
+	// Set via RegisterOptions.GeoJSON.
+	geojson bool
+
+	// geomCodec is the geometry backend used to marshal/unmarshal values
+	// other than go-geom's own geom.T (which Codec always understands
+	// natively). A nil geomCodec falls back to goGeomCodec. Set via
+	// RegisterOptions.GeomCodec.
+	geomCodec GeomCodec
+
+	// tel is non-nil when a tracer and/or meter provider was configured via
+	// WithTracerProvider/WithMeterProvider.
+	tel *telemetry
+}
+
+func (c Codec) backend() GeomCodec {
+	if c.geomCodec != nil {
+		return c.geomCodec
+	}
+	return goGeomCodec{}
+}
+
+func (c Codec) checkConstraints(g any) error {
+	return c.checkConstraintsWith(c.backend(), g)
+}
+
+// checkGoGeomConstraints enforces AllowedSRIDs/RejectZM against a go-geom
+// value directly, via goGeomCodec, regardless of the codec's configured
+// GeomCodec backend. GeoJSON conversion always decodes into go-geom's
+// geom.T (twpayne/go-geom's encoding/geojson has no equivalent for other
+// backends), so the GeoJSON codepaths must check constraints against
+// go-geom rather than whatever backend is configured.
+func (c Codec) checkGoGeomConstraints(g any) error {
+	return c.checkConstraintsWith(goGeomCodec{}, g)
+}
+
+func (c Codec) checkConstraintsWith(b GeomCodec, g any) error {
+	if len(c.allowedSRIDs) > 0 {
+		srid := b.SRID(g)
+		allowed := false
+		for _, s := range c.allowedSRIDs {
+			if s == srid {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("pgxgeom: SRID %d not allowed (allowed: %v)", srid, c.allowedSRIDs)
+		}
+	}
+	if c.rejectZM && b.HasZM(g) {
+		return fmt.Errorf("pgxgeom: geometry has a Z or M dimension, which is not allowed")
+	}
+	return nil
+}
+
+func (Codec) FormatSupported(format int16) bool {
+	return format == pgx.BinaryFormatCode || format == pgx.TextFormatCode
+}
+
+func (Codec) PreferredFormat() int16 {
+	return pgx.BinaryFormatCode
+}
+
+func (c Codec) PlanEncode(m *pgtype.Map, oid uint32, format int16, value any) pgtype.EncodePlan {
+	switch value.(type) {
+	case GeomValue:
+		return encodeGeomValuePlan{codec: c}
+	case geom.T:
+		return encodePlan{codec: c}
+	}
+	if c.geojson {
+		switch value.(type) {
+		case *json.RawMessage:
+			return encodeRawMessagePlan{codec: c}
+		case GeoJSONValuer:
+			return encodeGeoJSONValuerPlan{codec: c}
+		}
+	}
+	if c.backend().Is(value) {
+		return backendEncodePlan{codec: c}
+	}
+	return nil
+}
+
+func (c Codec) PlanScan(m *pgtype.Map, oid uint32, format int16, target any) pgtype.ScanPlan {
+	switch target.(type) {
+	case *geom.T:
+		return scanGeomTPlan{codec: c, format: format}
+	case ScanGeom:
+		return scanGeomPlan{codec: c, format: format}
+	}
+	if c.geojson {
+		switch target.(type) {
+		case *json.RawMessage:
+			return scanRawMessagePlan{codec: c, format: format}
+		case ScanGeoJSON:
+			return scanGeoJSONPlan{codec: c, format: format}
+		}
+	}
+	if scanPlan, ok := newScanConcretePlan(c, format, target); ok {
+		return scanPlan
+	}
+	return nil
+}
+
+func (c Codec) DecodeDatabaseSQLValue(m *pgtype.Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	if src == nil {
+		return nil, nil
+	}
+	g, err := c.decode(src, format)
+	if err != nil {
+		return nil, err
+	}
+	return c.backend().Marshal(g)
+}
+
+func (c Codec) DecodeValue(m *pgtype.Map, oid uint32, format int16, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+	return c.decode(src, format)
+}
+
+// decode unmarshals src (EWKB, or PostGIS's hex-encoded text form of it)
+// into a backend geometry value, enforcing constraints and recording
+// telemetry along the way.
+func (c Codec) decode(src []byte, format int16) (any, error) {
+	start := time.Now()
+	raw, err := rawEWKBBytes(src, format)
+	if err != nil {
+		return nil, err
+	}
+	g, unmarshalErr := c.backend().Unmarshal(raw)
+	err = unmarshalErr
+	if err == nil {
+		err = c.checkConstraints(g)
+	}
+	c.tel.recordDecode(context.Background(), g, len(raw), format, time.Since(start), err)
+	if unmarshalErr != nil {
+		return nil, fmt.Errorf("pgxgeom: failed to decode: %w", unmarshalErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// rawEWKBBytes returns src as raw binary EWKB, decoding PostGIS's
+// hex-encoded text format if necessary.
+func rawEWKBBytes(src []byte, format int16) ([]byte, error) {
+	switch format {
+	case pgx.BinaryFormatCode:
+		return src, nil
+	case pgx.TextFormatCode:
+		return hexDecode(src), nil
+	default:
+		return nil, fmt.Errorf("pgxgeom: unknown format code %d", format)
+	}
+}
+
+type encodePlan struct {
+	codec Codec
+}
+
+func (p encodePlan) Encode(value any, buf []byte) ([]byte, error) {
+	g := value.(geom.T)
+	if err := p.codec.checkGoGeomConstraints(g); err != nil {
+		return nil, err
+	}
+	data, err := ewkb.Marshal(g, ewkbByteOrder)
+	if err != nil {
+		return nil, fmt.Errorf("pgxgeom: failed to encode %T: %w", g, err)
+	}
+	p.codec.tel.recordEncode(context.Background(), g, len(data), pgx.BinaryFormatCode)
+	return append(buf, data...), nil
+}
+
+type encodeGeomValuePlan struct {
+	codec Codec
+}
+
+func (p encodeGeomValuePlan) Encode(value any, buf []byte) ([]byte, error) {
+	g, err := value.(GeomValue).GeomValue()
+	if err != nil {
+		return nil, fmt.Errorf("pgxgeom: failed to get geometry value from %T: %w", value, err)
+	}
+	if _, ok := g.(geom.T); ok {
+		return encodePlan{codec: p.codec}.Encode(g, buf)
+	}
+	return backendEncodePlan{codec: p.codec}.Encode(g, buf)
+}
+
+// backendEncodePlan encodes a value understood by the codec's GeomCodec
+// backend (anything other than go-geom's own geom.T, which encodePlan
+// handles directly).
+type backendEncodePlan struct {
+	codec Codec
+}
+
+func (p backendEncodePlan) Encode(value any, buf []byte) ([]byte, error) {
+	if err := p.codec.checkConstraints(value); err != nil {
+		return nil, err
+	}
+	data, err := p.codec.backend().Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("pgxgeom: failed to encode %T: %w", value, err)
+	}
+	p.codec.tel.recordEncode(context.Background(), value, len(data), pgx.BinaryFormatCode)
+	return append(buf, data...), nil
+}
+
+type scanGeomTPlan struct {
+	codec  Codec
+	format int16
+}
+
+func (p scanGeomTPlan) Scan(src []byte, target any) error {
+	dst := target.(*geom.T)
+	if src == nil {
+		*dst = nil
+		return nil
+	}
+	start := time.Now()
+	raw, err := rawEWKBBytes(src, p.format)
+	if err != nil {
+		return err
+	}
+	g, unmarshalErr := ewkb.Unmarshal(raw)
+	err = unmarshalErr
+	if err == nil {
+		err = p.codec.checkGoGeomConstraints(g)
+	}
+	p.codec.tel.recordDecode(context.Background(), g, len(raw), p.format, time.Since(start), err)
+	if unmarshalErr != nil {
+		return fmt.Errorf("pgxgeom: failed to decode: %w", unmarshalErr)
+	}
+	if err != nil {
+		return err
+	}
+	*dst = g
+	return nil
+}
+
+type scanGeomPlan struct {
+	codec  Codec
+	format int16
+}
+
+func (p scanGeomPlan) Scan(src []byte, target any) error {
+	dst := target.(ScanGeom)
+	if src == nil {
+		return dst.ScanGeom(nil)
+	}
+	start := time.Now()
+	raw, err := rawEWKBBytes(src, p.format)
+	if err != nil {
+		return err
+	}
+	g, unmarshalErr := p.codec.backend().Unmarshal(raw)
+	err = unmarshalErr
+	if err == nil {
+		err = p.codec.checkConstraints(g)
+	}
+	p.codec.tel.recordDecode(context.Background(), g, len(raw), p.format, time.Since(start), err)
+	if unmarshalErr != nil {
+		return fmt.Errorf("pgxgeom: failed to decode: %w", unmarshalErr)
+	}
+	if err != nil {
+		return err
+	}
+	return dst.ScanGeom(g)
+}
+
+// scanConcretePlan scans into a pointer to a concrete go-geom type, such as
+// *geom.Point or *geom.Polygon, failing if the decoded geometry is a
+// different concrete type.
+type scanConcretePlan struct {
+	codec  Codec
+	format int16
+}
+
+func newScanConcretePlan(codec Codec, format int16, target any) (pgtype.ScanPlan, bool) {
+	switch target.(type) {
+	case *geom.Point, *geom.LineString, *geom.Polygon,
+		*geom.MultiPoint, *geom.MultiLineString, *geom.MultiPolygon,
+		*geom.GeometryCollection:
+		return scanConcretePlan{codec: codec, format: format}, true
+	default:
+		return nil, false
+	}
+}
+
+func (p scanConcretePlan) Scan(src []byte, target any) error {
+	if src == nil {
+		return nil
+	}
+	start := time.Now()
+	raw, err := rawEWKBBytes(src, p.format)
+	if err != nil {
+		return err
+	}
+	g, unmarshalErr := ewkb.Unmarshal(raw)
+	err = unmarshalErr
+	if err == nil {
+		err = p.codec.checkGoGeomConstraints(g)
+	}
+	p.codec.tel.recordDecode(context.Background(), g, len(raw), p.format, time.Since(start), err)
+	if unmarshalErr != nil {
+		return fmt.Errorf("pgxgeom: failed to decode: %w", unmarshalErr)
+	}
+	if err != nil {
+		return err
+	}
+	if !assignConcrete(g, target) {
+		return fmt.Errorf("pgxgeom: got %T, want %T", g, target)
+	}
+	return nil
+}
+
+func assignConcrete(g geom.T, target any) bool {
+	switch dst := target.(type) {
+	case *geom.Point:
+		src, ok := g.(*geom.Point)
+		if ok {
+			*dst = *src
+		}
+		return ok
+	case *geom.LineString:
+		src, ok := g.(*geom.LineString)
+		if ok {
+			*dst = *src
+		}
+		return ok
+	case *geom.Polygon:
+		src, ok := g.(*geom.Polygon)
+		if ok {
+			*dst = *src
+		}
+		return ok
+	case *geom.MultiPoint:
+		src, ok := g.(*geom.MultiPoint)
+		if ok {
+			*dst = *src
+		}
+		return ok
+	case *geom.MultiLineString:
+		src, ok := g.(*geom.MultiLineString)
+		if ok {
+			*dst = *src
+		}
+		return ok
+	case *geom.MultiPolygon:
+		src, ok := g.(*geom.MultiPolygon)
+		if ok {
+			*dst = *src
+		}
+		return ok
+	case *geom.GeometryCollection:
+		src, ok := g.(*geom.GeometryCollection)
+		if ok {
+			*dst = *src
+		}
+		return ok
+	default:
+		return false
+	}
+}
+
+// ewkbByteOrder is the byte order pgx-geom uses when encoding EWKB for the
+// wire; PostGIS accepts either, we just need to be consistent with what we
+// read back.
+var ewkbByteOrder = binary.LittleEndian
+
+func hexDecode(src []byte) []byte {
+	dst := make([]byte, len(src)/2)
+	for i := range dst {
+		dst[i] = hexNibble(src[2*i])<<4 | hexNibble(src[2*i+1])
+	}
+	return dst
+}
+
+func hexNibble(b byte) byte {
+	switch {
+	case b >= '0' && b <= '9':
+		return b - '0'
+	case b >= 'a' && b <= 'f':
+		return b - 'a' + 10
+	case b >= 'A' && b <= 'F':
+		return b - 'A' + 10
+	default:
+		return 0
+	}
+}
+
+// RegisterOptions controls how Register wires up PostGIS types, letting
+// callers enforce a schema contract (allowed SRIDs, no Z/M) at the codec
+// layer instead of at every query site.
+type RegisterOptions struct {
+	// AllowedSRIDs, if non-empty, restricts the SRIDs that will be
+	// accepted when encoding or decoding a value; any other SRID is
+	// rejected with an error. For example, geography columns are
+	// conventionally SRID 4326 only, so a caller might pass
+	// AllowedSRIDs: []int{4326} to enforce that at the codec rather than
+	// trusting every call site to check it.
+	AllowedSRIDs []int
+
+	// RejectZM rejects geometries carrying a Z or M dimension, for
+	// applications that only ever want planar XY data.
+	RejectZM bool
+
+	// TypeNames overrides the set of PostGIS type names to register.
+	// The default is []string{"geometry", "geography"}. Include
+	// "geometry_dump" to also register the composite type returned by
+	// ST_Dump.
+	TypeNames []string
+
+	// GeoJSON opts into accepting *json.RawMessage, GeoJSON, and any type
+	// implementing GeoJSONValuer/ScanGeoJSON as query args and Scan
+	// targets, converting transparently between EWKB on the wire and RFC
+	// 7946 GeoJSON in Go.
+	GeoJSON bool
+
+	// GeomCodec overrides the geometry backend used to marshal/unmarshal
+	// values. The default, used when GeomCodec is nil, is backed by
+	// twpayne/go-geom. Subpackages such as pgxgeom/orb and
+	// pgxgeom/simplefeatures provide a GeomCodec for applications already
+	// committed to that ecosystem.
+	GeomCodec GeomCodec
+
+	// TracerProvider, if set, records a span for every EWKB
+	// encode/decode, with attributes describing the SRID, geometry type,
+	// point count, byte size, and wire format. Each span is a new root
+	// span, not a span event or a child of the query span. See
+	// WithTracerProvider.
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider, if set, records histograms of payload size and
+	// decode latency for every EWKB encode/decode. See WithMeterProvider.
+	MeterProvider metric.MeterProvider
+}
+
+// WithTracerProvider returns a RegisterOptions with TracerProvider set, for
+// composing with other options passed to Register, e.g.:
+//
+//	pgxgeom.Register(ctx, conn, pgxgeom.WithTracerProvider(tp))
+func WithTracerProvider(tp trace.TracerProvider) RegisterOptions {
+	return RegisterOptions{TracerProvider: tp}
+}
+
+// WithMeterProvider returns a RegisterOptions with MeterProvider set, for
+// composing with other options passed to Register, e.g.:
+//
+//	pgxgeom.Register(ctx, conn, pgxgeom.WithMeterProvider(mp))
+func WithMeterProvider(mp metric.MeterProvider) RegisterOptions {
+	return RegisterOptions{MeterProvider: mp}
+}
+
+// mergeRegisterOptions layers override onto base, keeping base's value for
+// any field override leaves at its zero value.
+func mergeRegisterOptions(base, override RegisterOptions) RegisterOptions {
+	if override.AllowedSRIDs != nil {
+		base.AllowedSRIDs = override.AllowedSRIDs
+	}
+	if override.RejectZM {
+		base.RejectZM = override.RejectZM
+	}
+	if override.TypeNames != nil {
+		base.TypeNames = override.TypeNames
+	}
+	if override.GeoJSON {
+		base.GeoJSON = override.GeoJSON
+	}
+	if override.GeomCodec != nil {
+		base.GeomCodec = override.GeomCodec
+	}
+	if override.TracerProvider != nil {
+		base.TracerProvider = override.TracerProvider
+	}
+	if override.MeterProvider != nil {
+		base.MeterProvider = override.MeterProvider
+	}
+	return base
+}
+
+// Register registers the PostGIS geometry type (and, unless overridden via
+// opts, the geography type) on conn, so that go-geom values and types
+// implementing ScanGeom/GeomValue can be used transparently as query
+// arguments and Scan targets. Multiple opts are merged in order, so that
+// composable single-field options such as WithTracerProvider and
+// WithMeterProvider can be passed alongside each other or a larger
+// RegisterOptions literal; a later, non-zero field wins.
+func Register(ctx context.Context, conn *pgx.Conn, opts ...RegisterOptions) error {
+	var opt RegisterOptions
+	for _, o := range opts {
+		opt = mergeRegisterOptions(opt, o)
+	}
+
+	typeNames := opt.TypeNames
+	if typeNames == nil {
+		typeNames = []string{"geometry", "geography"}
+	}
+
+	codec := Codec{
+		allowedSRIDs: opt.AllowedSRIDs,
+		rejectZM:     opt.RejectZM,
+		geojson:      opt.GeoJSON,
+		geomCodec:    opt.GeomCodec,
+		tel:          newTelemetry(opt.TracerProvider, opt.MeterProvider),
+	}
+
+	for _, typeName := range typeNames {
+		if typeName == "geometry_dump" {
+			pgType, err := conn.LoadType(ctx, typeName)
+			if err != nil {
+				return fmt.Errorf("pgxgeom: failed to load %s: %w", typeName, err)
+			}
+			conn.TypeMap().RegisterType(pgType)
+			continue
+		}
+
+		var oid uint32
+		err := conn.QueryRow(ctx, "select oid from pg_type where typname = $1", typeName).Scan(&oid)
+		if err != nil {
+			return fmt.Errorf("pgxgeom: failed to find OID for %s: %w", typeName, err)
+		}
+
+		conn.TypeMap().RegisterType(&pgtype.Type{
+			Name:  typeName,
+			OID:   oid,
+			Codec: codec,
+		})
+	}
+
+	return nil
+}
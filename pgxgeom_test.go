@@ -175,7 +175,7 @@ type CustomPoint struct {
 
 var errCustomPointScan = errors.New("invalid target for CustomPoint")
 
-func (c *CustomPoint) ScanGeom(v geom.T) error {
+func (c *CustomPoint) ScanGeom(v any) error {
 	concrete, ok := v.(*geom.Point)
 	if !ok {
 		return errCustomPointScan
@@ -184,7 +184,7 @@ func (c *CustomPoint) ScanGeom(v geom.T) error {
 	return nil
 }
 
-func (c *CustomPoint) GeomValue() (geom.T, error) {
+func (c *CustomPoint) GeomValue() (any, error) {
 	return c.Point, nil
 }
 
@@ -0,0 +1,88 @@
+// Package simplefeatures registers the PostGIS geometry and geography types
+// against peterstace/simplefeatures geometries instead of pgxgeom's default
+// twpayne/go-geom backend, for applications already committed to
+// simplefeatures (e.g. for its planar predicates).
+package simplefeatures
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/peterstace/simplefeatures/geom"
+
+	pgxgeom "github.com/twpayne/pgx-geom"
+)
+
+// GeomCodec implements pgxgeom.GeomCodec backed by peterstace/simplefeatures.
+//
+// simplefeatures' geom.Geometry has no SRID accessor, so SRID always
+// returns 0: a non-empty RegisterOptions.AllowedSRIDs rejects every
+// simplefeatures value unless it lists 0. Pin the SRID with a typmod column
+// (geometry(Point,4326)) instead.
+type GeomCodec struct{}
+
+func (GeomCodec) Is(value any) bool {
+	_, ok := value.(geom.Geometry)
+	return ok
+}
+
+func (GeomCodec) Marshal(value any) ([]byte, error) {
+	g, ok := value.(geom.Geometry)
+	if !ok {
+		return nil, fmt.Errorf("pgxgeom/simplefeatures: %T is not a geom.Geometry", value)
+	}
+	return g.AsBinary(), nil
+}
+
+func (GeomCodec) Unmarshal(data []byte) (any, error) {
+	g, err := geom.UnmarshalWKB(data)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (GeomCodec) SRID(any) int {
+	return 0
+}
+
+func (GeomCodec) HasZM(value any) bool {
+	switch value.(geom.Geometry).CoordinatesType() {
+	case geom.DimXYZ, geom.DimXYM, geom.DimXYZM:
+		return true
+	default:
+		return false
+	}
+}
+
+// Geom wraps a geom.Geometry so it can be used as a query argument or Scan
+// target, implementing pgxgeom.ScanGeom and pgxgeom.GeomValue.
+type Geom struct {
+	Geometry geom.Geometry
+}
+
+func (g *Geom) ScanGeom(v any) error {
+	geometry, ok := v.(geom.Geometry)
+	if !ok {
+		return fmt.Errorf("pgxgeom/simplefeatures: got %T, want geom.Geometry", v)
+	}
+	g.Geometry = geometry
+	return nil
+}
+
+func (g Geom) GeomValue() (any, error) {
+	return g.Geometry, nil
+}
+
+// Register registers the PostGIS geometry type (and, unless overridden via
+// opts, the geography type) on conn, backed by peterstace/simplefeatures
+// rather than pgxgeom's default twpayne/go-geom.
+func Register(ctx context.Context, conn *pgx.Conn, opts ...pgxgeom.RegisterOptions) error {
+	var opt pgxgeom.RegisterOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.GeomCodec = GeomCodec{}
+	return pgxgeom.Register(ctx, conn, opt)
+}